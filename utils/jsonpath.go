@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// contextIndexParents lists path segments after which a numeric token is an array index and
+// must be rendered in bracket notation, e.g. "parameters[0]". This enumerates every array-
+// bearing OpenAPI 3, Swagger 2 and AsyncAPI keywords a numeric path segment commonly follows; it
+// is not exhaustive (e.g. AsyncAPI "traits" is not listed), and isArrayIndex has no numeric-
+// threshold fallback: a parent absent from this table is treated as a key, since numeric object keys
+// (status codes under "responses", numeric property names under "schemas"/"properties") are far
+// more common in these documents than arrays under an unlisted keyword.
+var contextIndexParents = map[string]bool{
+	"parameters":  true,
+	"servers":     true,
+	"tags":        true,
+	"security":    true,
+	"allOf":       true,
+	"anyOf":       true,
+	"oneOf":       true,
+	"examples":    true,
+	"enum":        true,
+	"required":    true,
+	"consumes":    true,
+	"produces":    true,
+	"schemes":     true,
+	"messages":    true,
+	"prefixItems": true,
+}
+
+// tokenizeContext splits a raw context string into its path segments, respecting bracket
+// notation ([...]) and quoted segments so that keys containing literal dots, such as
+// "x-my.custom.ext", aren't split apart. The quote characters around a bracketed segment (e.g.
+// ['/users']) are kept as part of the token rather than stripped, since yamlpath.NewPath
+// requires them to tell a string bracket key apart from a numeric array index.
+//
+// It also protects dots inside an unbracketed vendor-extension key: once an accumulated segment
+// starts with the reserved "x-" extension prefix, a "." is treated as part of that key rather
+// than a separator, so "x-my.custom.ext" stays together as one token. Because nothing in the
+// raw context string marks where such a key ends, this keeps absorbing dots for the rest of the
+// string - fine for the common case of an extension key being the last path segment, but it
+// can't be combined with further real path segments after it.
+func tokenizeContext(context string) []string {
+	var segments []string
+	var current strings.Builder
+	inBracket := false
+	inQuote := false
+	var quoteChar rune
+
+	flush := func() {
+		segments = append(segments, current.String())
+		current.Reset()
+	}
+
+	runes := []rune(context)
+	for _, r := range runes {
+		switch {
+		case inQuote:
+			current.WriteRune(r)
+			if r == quoteChar {
+				inQuote = false
+			}
+		case r == '\'' || r == '"':
+			inQuote = true
+			quoteChar = r
+			current.WriteRune(r)
+		case r == '[':
+			inBracket = true
+			current.WriteRune(r)
+		case r == ']':
+			inBracket = false
+			current.WriteRune(r)
+		case r == '.' && !inBracket:
+			if strings.HasPrefix(current.String(), "x-") {
+				current.WriteRune(r)
+			} else {
+				flush()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return segments
+}
+
+// isArrayIndex decides whether a numeric token, found directly after parent in the path, should
+// be rendered as an array index ([N]) or a literal object key. It's driven entirely by
+// contextIndexParents - see its doc comment for why no threshold fallback is needed.
+func isArrayIndex(parent string) bool {
+	return contextIndexParents[parent]
+}
+
+// normalizeContext tokenizes context and reassembles it into a JSONPath that yamlpath.NewPath
+// can parse: "(root)" becomes "$", bracket segments are merged onto the preceding segment, bare
+// numeric segments are merged as an index or kept as a key per isArrayIndex, and a token that
+// still contains a literal "." (a merged vendor-extension key from tokenizeContext) is wrapped
+// in quoted bracket notation, since yamlpath has no other way to tell a dot inside a key apart
+// from a path separator.
+func normalizeContext(context string) string {
+	tokens := tokenizeContext(context)
+	var cleaned []string
+
+	for i, t := range tokens {
+		switch {
+		case t == "(root)":
+			cleaned = append(cleaned, "$")
+
+		case strings.HasPrefix(t, "["):
+			if len(cleaned) > 0 {
+				cleaned[len(cleaned)-1] += t
+			} else {
+				cleaned = append(cleaned, t)
+			}
+
+		case strings.Contains(t, "."):
+			quoted := fmt.Sprintf("['%s']", t)
+			if len(cleaned) > 0 {
+				cleaned[len(cleaned)-1] += quoted
+			} else {
+				cleaned = append(cleaned, quoted)
+			}
+
+		default:
+			if _, err := strconv.Atoi(t); err == nil {
+				var parent string
+				if i > 0 {
+					parent = tokens[i-1]
+				}
+				if isArrayIndex(parent) {
+					if len(cleaned) > 0 {
+						cleaned[len(cleaned)-1] += fmt.Sprintf("[%v]", t)
+					} else {
+						cleaned = append(cleaned, fmt.Sprintf("[%v]", t))
+					}
+					continue
+				}
+			}
+			cleaned = append(cleaned, strings.ReplaceAll(t, "(root)", "$"))
+		}
+	}
+	return strings.Join(cleaned, ".")
+}
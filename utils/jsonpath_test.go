@@ -0,0 +1,26 @@
+package utils
+
+import "testing"
+
+func TestFixContext(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"root only", "(root)", "$"},
+		{"already bracket-quoted dotted key", "(root).['x-my.custom.ext']", "$['x-my.custom.ext']"},
+		{"unbracketed dotted extension key", "(root).x-my.custom.ext", "$['x-my.custom.ext']"},
+		{"status code under responses", "(root).paths./pet.get.responses.200", "$.paths./pet.get.responses.200"},
+		{"quoted bracket key preserved", "(root).paths.['/users'].get", "$.paths['/users'].get"},
+		{"numeric index under parameters", "(root).paths./pet.get.parameters.0", "$.paths./pet.get.parameters[0]"},
+		{"numeric key under an unlisted parent defaults to a key", "(root).customMap.5", "$.customMap.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FixContext(tt.in); got != tt.want {
+				t.Errorf("FixContext(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VisitFunc is called for every key/value pair discovered while walking a mapping node. path is
+// the JSON-pointer-style sequence of keys (and array indices) leading to this pair. Returning
+// true stops the walk early.
+type VisitFunc func(path []string, key, val *yaml.Node) bool
+
+// NodeWalker provides predicate-based, bounds-safe traversal of a yaml.Node tree. It replaces
+// the recursion that used to live inline in FindFirstKeyNode and FindKeyNode, which could index
+// out of range on the last element of a node list and, in FindKeyNode's case, could return the
+// parent node instead of the matched key.
+type NodeWalker struct{}
+
+// NewNodeWalker creates a new NodeWalker.
+func NewNodeWalker() *NodeWalker {
+	return &NodeWalker{}
+}
+
+// Walk traverses root depth-first, calling visit for every key/value pair found in mapping
+// nodes, correctly pairing each key with its value (nodes.Content holds keys at even indices
+// and values at odd indices). Walk stops as soon as visit returns true.
+func (w *NodeWalker) Walk(root *yaml.Node, visit VisitFunc) {
+	w.walk(root, nil, visit)
+}
+
+func (w *NodeWalker) walk(node *yaml.Node, path []string, visit VisitFunc) bool {
+	if node == nil {
+		return false
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if w.walk(child, path, visit) {
+				return true
+			}
+		}
+
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			val := node.Content[i+1]
+			if visit(path, key, val) {
+				return true
+			}
+			if w.walk(val, append(append([]string{}, path...), key.Value), visit) {
+				return true
+			}
+		}
+
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if w.walk(child, append(append([]string{}, path...), strconv.Itoa(i)), visit) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// KeyMatch is a single result returned by FindAllKeys: the matched key and value node, along
+// with the path used to reach them.
+type KeyMatch struct {
+	Path  []string
+	Key   *yaml.Node
+	Value *yaml.Node
+}
+
+// FindAllKeys walks root and returns every mapping key/value pair whose key matches key, along
+// with the path to each match.
+func (w *NodeWalker) FindAllKeys(root *yaml.Node, key string) []KeyMatch {
+	var matches []KeyMatch
+	w.Walk(root, func(path []string, k, v *yaml.Node) bool {
+		if k.Value == key {
+			matches = append(matches, KeyMatch{
+				Path:  append(append([]string{}, path...), k.Value),
+				Key:   k,
+				Value: v,
+			})
+		}
+		return false
+	})
+	return matches
+}
+
+// FindFirstKey searches nodes for a node whose Value matches key, returning it together with
+// the node immediately following it in nodes. This mirrors the calling convention of a mapping
+// node's own Content slice, which is flat and alternates key, value, key, value, ... - passing
+// such a slice in directly (rather than a synthetic root) is what callers like FindFirstKeyNode
+// have always done. It also recurses into every node's own Content, so a match nested arbitrarily
+// deep in the document is still found, not just one at the top level of nodes.
+//
+// It's bounds-safe: a match on the very last element of nodes, with no following value, is
+// simply not reported rather than panicking.
+func (w *NodeWalker) FindFirstKey(nodes []*yaml.Node, key string) (keyNode, valueNode *yaml.Node) {
+	for i, v := range nodes {
+		if key != "" && v.Value == key && i+1 < len(nodes) {
+			return v, nodes[i+1]
+		}
+		if len(v.Content) > 0 {
+			if k, val := w.FindFirstKey(v.Content, key); k != nil && val != nil {
+				return k, val
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format describes the serialization a specification was supplied in.
+type Format string
+
+const (
+	// JSONFormat is used when a specification was supplied as JSON.
+	JSONFormat Format = "json"
+
+	// YAMLFormat is used when a specification was supplied as YAML.
+	YAMLFormat Format = "yaml"
+)
+
+// SpecInfo contains everything known about a specification after it has been detected: which
+// family it belongs to (OpenAPI 3, Swagger 2 or AsyncAPI), its exact version, the format it was
+// supplied in, and the parsed root of the document so callers don't need to re-parse it.
+type SpecInfo struct {
+
+	// SpecType is one of OpenApi3, OpenApi2 or AsyncApi.
+	SpecType string
+
+	// Version is the exact version string taken from the document, e.g. "3.1.0".
+	Version string
+
+	// SpecFormat is the format the document was originally supplied in.
+	SpecFormat Format
+
+	// RootNode is the parsed root of the document.
+	RootNode *yaml.Node
+}
+
+// IsOpenAPI3 returns true if the detected specification is OpenAPI 3+.
+func (si *SpecInfo) IsOpenAPI3() bool {
+	return si.SpecType == OpenApi3
+}
+
+// IsSwagger2 returns true if the detected specification is Swagger / OpenAPI 2.
+func (si *SpecInfo) IsSwagger2() bool {
+	return si.SpecType == OpenApi2
+}
+
+// IsAsyncAPI returns true if the detected specification is AsyncAPI.
+func (si *SpecInfo) IsAsyncAPI() bool {
+	return si.SpecType == AsyncApi
+}
+
+// MinorVersion returns the minor version component of Version, e.g. "1" for "3.1.0", so rules
+// can gate behaviour on things like OpenAPI 3.1 vs 3.0 features (webhooks, JSON Schema 2020-12).
+// It returns an empty string if Version doesn't contain a minor component.
+func (si *SpecInfo) MinorVersion() string {
+	parts := strings.Split(si.Version, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// DetectSpecInfo inspects data and returns a SpecInfo describing what kind of specification it
+// is, its exact version, and the format it was supplied in (JSON or YAML). It tolerates leading
+// BOMs (via LoadSpec) and works for both JSON and YAML entrypoints, parsing the document exactly
+// once.
+func DetectSpecInfo(data []byte) (*SpecInfo, error) {
+	root, format, err := LoadSpec(data)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &SpecInfo{
+		SpecFormat: format,
+		RootNode:   root,
+	}
+
+	if v := topLevelValue(root, "openapi"); v != nil {
+		info.SpecType = OpenApi3
+		info.Version = v.Value
+		return info, nil
+	}
+	if v := topLevelValue(root, "swagger"); v != nil {
+		info.SpecType = OpenApi2
+		info.Version = v.Value
+		return info, nil
+	}
+	if v := topLevelValue(root, "asyncapi"); v != nil {
+		info.SpecType = AsyncApi
+		info.Version = v.Value
+		return info, nil
+	}
+
+	return nil, fmt.Errorf("utils: unable to determine specification type: no openapi, swagger or asyncapi version key found")
+}
+
+// topLevelValue returns the value of key if it appears directly on root's top-level mapping,
+// without descending into nested content. Spec-family markers (openapi/swagger/asyncapi) are
+// only ever valid at the document root per the OpenAPI/Swagger/AsyncAPI specs, so unlike
+// FindFirstKeyNode this deliberately does not search the rest of the document - otherwise a
+// nested property that happens to be named e.g. "swagger" would cause a false positive.
+func topLevelValue(root *yaml.Node, key string) *yaml.Node {
+	if root == nil || len(root.Content) == 0 {
+		return nil
+	}
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
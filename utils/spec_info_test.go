@@ -0,0 +1,44 @@
+package utils
+
+import "testing"
+
+func TestDetectSpecInfo_TopLevelOnly(t *testing.T) {
+	yamlData := `
+openapi: 3.0.0
+paths:
+  /foo:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                properties:
+                  swagger:
+                    type: string
+`
+	info, err := DetectSpecInfo([]byte(yamlData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !info.IsOpenAPI3() {
+		t.Errorf("expected OpenAPI 3, got SpecType=%q Version=%q", info.SpecType, info.Version)
+	}
+	if info.Version != "3.0.0" {
+		t.Errorf("expected version 3.0.0, got %q", info.Version)
+	}
+}
+
+func TestDetectSpecInfo_NoSpecKey(t *testing.T) {
+	yamlData := `
+paths:
+  /foo:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+	if _, err := DetectSpecInfo([]byte(yamlData)); err == nil {
+		t.Error("expected an error when no openapi/swagger/asyncapi key is present, got none")
+	}
+}
@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
 	"gopkg.in/yaml.v3"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -116,38 +118,23 @@ func ExtractValueFromInterfaceMap(name string, raw interface{}) interface{} {
 	return nil
 }
 
-// FindFirstKeyNode will locate the first key and value yaml.Node based on a key.
+// FindFirstKeyNode will locate the first key and value yaml.Node based on a key. nodes is
+// typically a mapping node's own Content (a flat, alternating key/value/key/value/... slice),
+// but any node's Content works, since matches are also searched for recursively. It delegates to
+// NodeWalker so the search is bounds-safe (the original recursion could index nodes[i+1] out of
+// range on the last element of nodes).
 func FindFirstKeyNode(key string, nodes []*yaml.Node) (keyNode *yaml.Node, valueNode *yaml.Node) {
-
-	for i, v := range nodes {
-		if key != "" && key == v.Value {
-			return v, nodes[i+1] // next node is what we need.
-		}
-		if len(v.Content) > 0 {
-			x, y := FindFirstKeyNode(key, v.Content)
-			if x != nil && y != nil {
-				return x, y
-			}
-		}
-	}
-	return nil, nil
+	return NewNodeWalker().FindFirstKey(nodes, key)
 }
 
-// FindKeyNode is a non-recursive search of an  yaml.Node Content for a child node with a key.
-// Returns the key and value
+// FindKeyNode is now a thin alias for FindFirstKeyNode, kept for existing callers. The original
+// implementation only searched nodes' direct children and one level of their Content, and
+// returned the wrong node (the parent, not the key) on a match; delegating to FindFirstKeyNode
+// fixes both, but also means the search is now a full recursive walk of the whole subtree rather
+// than the old bounded scope - keep that in mind if callers relied on it only matching nearby
+// keys.
 func FindKeyNode(key string, nodes []*yaml.Node) (keyNode *yaml.Node, valueNode *yaml.Node) {
-
-	for i, v := range nodes {
-		if key == v.Value {
-			return v, nodes[i+1] // next node is what we need.
-		}
-		for x, j := range v.Content {
-			if key == j.Value {
-				return v, v.Content[x+1] // next node is what we need.
-			}
-		}
-	}
-	return nil, nil
+	return FindFirstKeyNode(key, nodes)
 }
 
 // IsNodeMap checks if the node is a map type
@@ -180,28 +167,11 @@ func IsNodeBoolValue(node *yaml.Node) bool {
 	return node.Tag == "!!bool"
 }
 
-// FixContext will clean up a JSONpath string to be correctly traversable.
+// FixContext will clean up a JSONpath string to be correctly traversable. It is a compatibility
+// shim kept for existing callers; the actual work is done by normalizeContext's tokenizer, which
+// replaces the old "numbers below 200 are indices" heuristic.
 func FixContext(context string) string {
-
-	tokens := strings.Split(context, ".")
-	var cleaned = []string{}
-	for i, t := range tokens {
-
-		if v, err := strconv.Atoi(t); err == nil {
-
-			if v < 200 { // codes start here
-				if cleaned[i-1] != "" {
-					cleaned[i-1] += fmt.Sprintf("[%v]", t)
-				}
-			} else {
-				cleaned = append(cleaned, t)
-			}
-			continue
-		}
-		cleaned = append(cleaned, strings.ReplaceAll(t, "(root)", "$"))
-
-	}
-	return strings.Join(cleaned, ".")
+	return normalizeContext(context)
 }
 
 // IsJSON will tell you if a string is JSON or not.
@@ -233,19 +203,261 @@ func IsYAML(testString string) bool {
 	return err == nil
 }
 
-// ConvertYAMLtoJSON will do exactly what you think it will. It will deserialize YAML into serialized JSON.
+// LoadSpec parses data into a single *yaml.Node representation, along with the Format it was
+// detected to be in, regardless of whether it was supplied as JSON or YAML. JSON input is parsed
+// directly via yaml.Unmarshal rather than encoding/json first, since yaml.v3 treats JSON as a
+// subset of YAML - this avoids a double-parse and the float64 coercion and key reordering that
+// come from going through encoding/json for JSON-sourced documents. A leading UTF-8 BOM is
+// stripped before format detection so BOM-prefixed JSON isn't mistaken for YAML.
+func LoadSpec(data []byte) (*yaml.Node, Format, error) {
+	data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+
+	format := YAMLFormat
+	if IsJSON(strings.TrimSpace(string(data))) {
+		format = JSONFormat
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, format, err
+	}
+	return &root, format, nil
+}
+
+// ConvertYAMLtoJSON will convert YAML into JSON by walking the parsed *yaml.Node tree directly,
+// rather than round-tripping through map[string]interface{} and encoding/json. This preserves
+// the original document's key order, avoids coercing large integers (status codes, version
+// numbers) into float64, and expands YAML merge keys ("<<") the way a map[string]interface{}
+// round-trip used to.
 func ConvertYAMLtoJSON(yamlData []byte) ([]byte, error) {
-	var decodedYaml map[string]interface{}
-	err := yaml.Unmarshal(yamlData, &decodedYaml)
-	if err != nil {
+	var root yaml.Node
+	if err := yaml.Unmarshal(yamlData, &root); err != nil {
 		return nil, err
 	}
-	jsonData, err := json.Marshal(decodedYaml)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := nodeToJSON(&root, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ConvertJSONtoYAML will convert JSON into YAML. Since yaml.v3 parses JSON as a subset of YAML,
+// the input is unmarshalled directly into a *yaml.Node tree and re-marshalled, so no
+// intermediate interface{} representation is needed.
+func ConvertJSONtoYAML(jsonData []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(jsonData, &root); err != nil {
 		return nil, err
 	}
-	return jsonData, nil
+	return yaml.Marshal(&root)
+}
+
+// nodeToJSON writes the JSON representation of node to buf, recursing through mapping and
+// sequence nodes so that mapping keys are emitted in the order they appear in the document.
+// Mapping nodes are expanded via resolveMappingPairs so that merge keys ("<<") are resolved
+// rather than emitted as a literal "<<" property.
+func nodeToJSON(node *yaml.Node, buf *bytes.Buffer) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return nodeToJSON(node.Content[0], buf)
 
+	case yaml.MappingNode:
+		pairs, err := resolveMappingPairs(node)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('{')
+		for i, p := range pairs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := marshalJSONNoEscape(p.key)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := nodeToJSON(p.value, buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := nodeToJSON(child, buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+	case yaml.ScalarNode:
+		return scalarToJSON(node, buf)
+
+	case yaml.AliasNode:
+		return nodeToJSON(node.Alias, buf)
+
+	default:
+		return fmt.Errorf("utils: cannot convert yaml node of kind %v to JSON", node.Kind)
+	}
+	return nil
+}
+
+// scalarToJSON writes the JSON literal for a scalar node, using its resolved tag (!!int,
+// !!float, !!bool, !!null, !!str) to pick the correct literal instead of round-tripping through
+// interface{}, which is what silently turned integers like status codes into float64.
+//
+// !!int and !!float values are decoded rather than passed through as raw text: YAML allows
+// numeric literals - 0x1A, 0o17, 1_000_000, +5, .inf, .nan - that carry an !!int/!!float tag but
+// are not legal JSON numbers, so writing node.Value straight through would emit invalid JSON.
+func scalarToJSON(node *yaml.Node, buf *bytes.Buffer) error {
+	switch node.Tag {
+	case "!!null":
+		buf.WriteString("null")
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return err
+		}
+		if b {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case "!!int":
+		var i int64
+		if err := node.Decode(&i); err != nil {
+			var u uint64
+			if err := node.Decode(&u); err != nil {
+				return fmt.Errorf("utils: cannot represent YAML value %q as a JSON number: %w", node.Value, err)
+			}
+			buf.WriteString(strconv.FormatUint(u, 10))
+			return nil
+		}
+		buf.WriteString(strconv.FormatInt(i, 10))
+	case "!!float":
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return fmt.Errorf("utils: cannot represent YAML value %q as a JSON number: %w", node.Value, err)
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("utils: YAML value %q has no JSON representation (infinity/NaN)", node.Value)
+		}
+		str, err := marshalJSONNoEscape(f)
+		if err != nil {
+			return err
+		}
+		buf.Write(str)
+	default:
+		str, err := marshalJSONNoEscape(node.Value)
+		if err != nil {
+			return err
+		}
+		buf.Write(str)
+	}
+	return nil
+}
+
+// marshalJSONNoEscape behaves like json.Marshal but leaves '<', '>' and '&' untouched instead of
+// escaping them to \uXXXX sequences, so that keys and values carried over verbatim from the source
+// YAML (e.g. a merge key "<<", or a property literally named "a<b") don't get needlessly
+// mangled - important for the diff-friendly output this conversion exists for.
+func marshalJSONNoEscape(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// jsonPair is a single resolved key/value pair destined for JSON object output.
+type jsonPair struct {
+	key   string
+	value *yaml.Node
+}
+
+// resolveMappingPairs returns node's own key/value pairs in document order, followed by the pairs
+// pulled in through YAML merge keys ("<<: *anchor" or "<<: [*a, *b]") from the mapping(s) they
+// reference; merged pairs always come after the explicit ones, wherever "<<" appears in node.
+// Explicit keys on node always win over merged ones; when multiple merge sources conflict, the
+// earlier source wins - both per the YAML merge key spec.
+func resolveMappingPairs(node *yaml.Node) ([]jsonPair, error) {
+	var explicit []jsonPair
+	var mergeSources []*yaml.Node
+	seen := make(map[string]bool)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		val := node.Content[i+1]
+		if key.Tag == "!!merge" {
+			mergeSources = append(mergeSources, val)
+			continue
+		}
+		if seen[key.Value] {
+			continue
+		}
+		seen[key.Value] = true
+		explicit = append(explicit, jsonPair{key: key.Value, value: val})
+	}
+
+	pairs := explicit
+	for _, src := range mergeSources {
+		merged, err := resolveMergeSource(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range merged {
+			if seen[p.key] {
+				continue
+			}
+			seen[p.key] = true
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs, nil
+}
+
+// resolveMergeSource resolves the value of a "<<" merge key, which may be an alias to a single
+// mapping, or a sequence of such aliases/mappings, and returns their combined key/value pairs.
+func resolveMergeSource(node *yaml.Node) ([]jsonPair, error) {
+	resolved := node
+	if resolved.Kind == yaml.AliasNode {
+		resolved = resolved.Alias
+	}
+
+	if resolved.Kind == yaml.SequenceNode {
+		var all []jsonPair
+		seen := make(map[string]bool)
+		for _, item := range resolved.Content {
+			pairs, err := resolveMergeSource(item)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range pairs {
+				if seen[p.key] {
+					continue
+				}
+				seen[p.key] = true
+				all = append(all, p)
+			}
+		}
+		return all, nil
+	}
+
+	if resolved.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("utils: merge key (\"<<\") must reference a mapping or a sequence of mappings, got %v", resolved.Kind)
+	}
+	return resolveMappingPairs(resolved)
 }
 
 //func parseVersionTypeData(d interface{}) string {
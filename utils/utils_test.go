@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConvertYAMLtoJSON_NumericLiterals(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"hex int", "a: 0x1A", `{"a":26}`},
+		{"octal int", "a: 0o17", `{"a":15}`},
+		{"underscored int", "a: 1_000_000", `{"a":1000000}`},
+		{"leading plus", "a: +5", `{"a":5}`},
+		{"plain int", "a: 200", `{"a":200}`},
+		{"plain float", "a: 1.5", `{"a":1.5}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertYAMLtoJSON([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertYAMLtoJSON_NonFiniteFloatRejected(t *testing.T) {
+	for _, yamlData := range []string{"a: .inf", "a: -.inf", "a: .nan"} {
+		if _, err := ConvertYAMLtoJSON([]byte(yamlData)); err == nil {
+			t.Errorf("expected an error converting %q to JSON, got none", yamlData)
+		}
+	}
+}
+
+func TestConvertYAMLtoJSON_MergeKeys(t *testing.T) {
+	yamlData := `
+base: &base
+  name: widget
+  price: 10
+item:
+  <<: *base
+  price: 20
+`
+	got, err := ConvertYAMLtoJSON([]byte(yamlData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"base":{"name":"widget","price":10},"item":{"price":20,"name":"widget"}}`
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestConvertYAMLtoJSON_NoHTMLEscaping(t *testing.T) {
+	yamlData := "a<b: \"x<y&z\""
+	got, err := ConvertYAMLtoJSON([]byte(yamlData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"a<b":"x<y&z"}`
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLoadSpec_StripsLeadingBOM(t *testing.T) {
+	bom := []byte{0xEF, 0xBB, 0xBF}
+	data := append(bom, []byte(`{"a": 1}`)...)
+
+	_, format, err := LoadSpec(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if format != JSONFormat {
+		t.Errorf("expected JSONFormat for a BOM-prefixed JSON document, got %v", format)
+	}
+}
+
+func TestFindKeyNode_FlatMappingContent(t *testing.T) {
+	// The documented calling convention: nodes is a mapping node's own Content, a flat
+	// alternating key/value/key/value/... slice - not a root/document node.
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte("a: 1\nb: 2\n"), &root); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mapping := root.Content[0]
+
+	k, v := FindKeyNode("b", mapping.Content)
+	if k == nil || v == nil || v.Value != "2" {
+		t.Fatalf("expected to find top-level key 'b' in a flat mapping Content slice, got key=%v val=%v", k, v)
+	}
+}
+
+func TestFindKeyNode_RecursesIntoWholeSubtree(t *testing.T) {
+	// FindKeyNode/FindFirstKeyNode also still find a match nested arbitrarily deep, not just
+	// one directly present in the slice passed in.
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(`
+a:
+  b:
+    target: found-me
+`), &root); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	k, v := FindKeyNode("target", root.Content)
+	if k == nil || v == nil || v.Value != "found-me" {
+		t.Fatalf("expected to find nested key 'target', got key=%v val=%v", k, v)
+	}
+}
+
+func TestFindFirstKeyNode_BoundsSafe(t *testing.T) {
+	// A dangling key with no following value used to panic with nodes[i+1] out of range.
+	// It should now just report no match instead of crashing.
+	nodes := []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "dangling"},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("FindFirstKeyNode panicked: %v", r)
+		}
+	}()
+	k, v := FindFirstKeyNode("dangling", nodes)
+	if k != nil || v != nil {
+		t.Errorf("expected no match for a dangling key, got key=%v val=%v", k, v)
+	}
+}